@@ -0,0 +1,110 @@
+package snetlog
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+//callerBaseSkip is the number of stack frames between resolveCaller's own
+//frame and the frame of whatever called one of Log's logging methods
+//(Erro, ErroDepth, contextLogger.Erro, Verbose.Trace, ...): that method's
+//own frame, plus output's frame. Every logging method calls output
+//directly and output calls resolveCaller directly, so this constant holds
+//regardless of which method was used; a *Depth method's depth argument is
+//added on top of it for callers one or more wrapper frames further out.
+const callerBaseSkip = 3
+
+//CallerInfo identifies the source location that produced a log entry.
+type CallerInfo struct {
+	File     string
+	Line     int
+	Function string
+}
+
+func resolveCaller(skip int) *CallerInfo {
+	pc, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return nil
+	}
+
+	name := ""
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		name = fn.Name()
+	}
+
+	return &CallerInfo{File: file, Line: line, Function: name}
+}
+
+//captureStack walks the current goroutine's stack with runtime.Callers and
+//renders it as "function\n\tfile:line" per frame, the way an Alert,
+//Emergency or Erro entry's Stack is populated.
+func captureStack(skip int) string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip, pcs)
+	if n == 0 {
+		return ""
+	}
+
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var buf bytes.Buffer
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&buf, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return buf.String()
+}
+
+//backtraceAt holds the set of "file.go:line" locations configured via
+//SetBacktraceAt; an entry logged from a matching location gets a stack
+//dump attached even if its severity wouldn't normally warrant one.
+var backtraceAt = struct {
+	mu  sync.RWMutex
+	set map[string]bool
+}{set: map[string]bool{}}
+
+//SetBacktraceAt configures a comma-separated list of "file.go:line"
+//locations that should get a stack dump attached to the single entry
+//logged from there, e.g. SetBacktraceAt("worker.go:123,pool.go:45"). This
+//is useful for debugging a specific hot spot without turning on stack
+//dumps globally.
+func SetBacktraceAt(spec string) error {
+	set := make(map[string]bool)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if !strings.Contains(part, ":") {
+			return fmt.Errorf("snetlog: invalid backtrace location %q, want file.go:line", part)
+		}
+		set[part] = true
+	}
+
+	backtraceAt.mu.Lock()
+	backtraceAt.set = set
+	backtraceAt.mu.Unlock()
+	return nil
+}
+
+func shouldBacktrace(caller *CallerInfo) bool {
+	if caller == nil {
+		return false
+	}
+
+	backtraceAt.mu.RLock()
+	defer backtraceAt.mu.RUnlock()
+	if len(backtraceAt.set) == 0 {
+		return false
+	}
+
+	key := fmt.Sprintf("%s:%d", filepath.Base(caller.File), caller.Line)
+	return backtraceAt.set[key]
+}
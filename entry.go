@@ -0,0 +1,25 @@
+package snetlog
+
+import (
+	"time"
+
+	"cloud.google.com/go/logging"
+)
+
+//Entry represents a single structured log record before it is handed to a
+//Formatter. Fields carries the key/value context accumulated via WithField
+//and WithFields.
+type Entry struct {
+	Severity logging.Severity
+	Time     time.Time
+	Message  string
+	Fields   map[string]interface{}
+
+	//Caller identifies where the entry was logged from, if caller
+	//resolution succeeded.
+	Caller *CallerInfo
+	//Stack holds a goroutine stack dump, captured automatically for
+	//Erro/Alert/Emergency entries or for any entry logged from a
+	//location configured via SetBacktraceAt.
+	Stack string
+}
@@ -0,0 +1,115 @@
+package snetlog
+
+import "cloud.google.com/go/logging"
+
+//contextLogger is a Logger that carries a fixed set of fields, added via
+//WithField/WithFields, on top of a base *Log. It exists so callers can do
+//log.WithField("req", id).Info("handled") without the base Log itself
+//needing to remember per-call context.
+type contextLogger struct {
+	base   *Log
+	fields map[string]interface{}
+}
+
+func mergeFields(base map[string]interface{}, extra map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(extra))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
+
+//WithField returns a child Logger that annotates every entry it logs with
+//key=value, in addition to whatever this Log already carries.
+func (l *Log) WithField(key string, value interface{}) Logger {
+	return &contextLogger{base: l, fields: map[string]interface{}{key: value}}
+}
+
+//WithFields returns a child Logger that annotates every entry it logs with
+//fields, in addition to whatever this Log already carries.
+func (l *Log) WithFields(fields map[string]interface{}) Logger {
+	return &contextLogger{base: l, fields: mergeFields(nil, fields)}
+}
+
+//WithField ...
+func (l *contextLogger) WithField(key string, value interface{}) Logger {
+	return &contextLogger{base: l.base, fields: mergeFields(l.fields, map[string]interface{}{key: value})}
+}
+
+//WithFields ...
+func (l *contextLogger) WithFields(fields map[string]interface{}) Logger {
+	return &contextLogger{base: l.base, fields: mergeFields(l.fields, fields)}
+}
+
+//Trace ...
+func (l *contextLogger) Trace(args ...interface{}) {
+	l.base.output(logging.Debug, l.fields, 0, "", args...)
+}
+
+//Tracef ...
+func (l *contextLogger) Tracef(format string, args ...interface{}) {
+	l.base.output(logging.Debug, l.fields, 0, format, args...)
+}
+
+//Info ...
+func (l *contextLogger) Info(args ...interface{}) {
+	l.base.output(logging.Info, l.fields, 0, "", args...)
+}
+
+//Infof ...
+func (l *contextLogger) Infof(format string, args ...interface{}) {
+	l.base.output(logging.Info, l.fields, 0, format, args...)
+}
+
+//Notice ...
+func (l *contextLogger) Notice(args ...interface{}) {
+	l.base.output(logging.Notice, l.fields, 0, "", args...)
+}
+
+//Noticef ...
+func (l *contextLogger) Noticef(format string, args ...interface{}) {
+	l.base.output(logging.Notice, l.fields, 0, format, args...)
+}
+
+//Warn ...
+func (l *contextLogger) Warn(args ...interface{}) {
+	l.base.output(logging.Warning, l.fields, 0, "", args...)
+}
+
+//Warnf ...
+func (l *contextLogger) Warnf(format string, args ...interface{}) {
+	l.base.output(logging.Warning, l.fields, 0, format, args...)
+}
+
+//Erro ...
+func (l *contextLogger) Erro(args ...interface{}) {
+	l.base.output(logging.Error, l.fields, 0, "", args...)
+}
+
+//Errof ...
+func (l *contextLogger) Errof(format string, args ...interface{}) {
+	l.base.output(logging.Error, l.fields, 0, format, args...)
+}
+
+//Alert ...
+func (l *contextLogger) Alert(args ...interface{}) {
+	l.base.output(logging.Alert, l.fields, 0, "", args...)
+}
+
+//Alertf ...
+func (l *contextLogger) Alertf(format string, args ...interface{}) {
+	l.base.output(logging.Alert, l.fields, 0, format, args...)
+}
+
+//Emergency ...
+func (l *contextLogger) Emergency(args ...interface{}) {
+	l.base.output(logging.Emergency, l.fields, 0, "", args...)
+}
+
+//Emergencyf ...
+func (l *contextLogger) Emergencyf(format string, args ...interface{}) {
+	l.base.output(logging.Emergency, l.fields, 0, format, args...)
+}
@@ -0,0 +1,209 @@
+package snetlog
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+//fileSink writes entries to a file, buffering them and flushing once a
+//second (or sooner, via Flush/Rotate), and supports size-based rotation
+//via MaxSizeMB/MaxBackups.
+type fileSink struct {
+	mux       sync.Mutex
+	fileName  string
+	buffer    *bytes.Buffer
+	file      *os.File
+	fileSize  int64
+	formatter Formatter
+
+	maxSizeMB  int64
+	maxBackups int
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newFileSink(config *FileConfig) *fileSink {
+	s := &fileSink{
+		fileName:   config.FileName,
+		buffer:     bytes.NewBuffer(make([]byte, 0, 3072)),
+		formatter:  config.Formatter,
+		maxSizeMB:  config.MaxSizeMB,
+		maxBackups: config.MaxBackups,
+		done:       make(chan struct{}),
+	}
+
+	if err := s.openLocked(); err != nil {
+		log.Printf("snetlog: failed to open log file %q: %v", config.FileName, err)
+	}
+
+	go s.flushLoop()
+
+	return s
+}
+
+func (s *fileSink) flushLoop() {
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Flush(); err != nil {
+				log.Printf("snetlog: file sink flush failed: %v", err)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *fileSink) Write(entry Entry) error {
+	formatter := s.formatter
+	if formatter == nil {
+		formatter = TextFormatter{}
+	}
+
+	rendered, err := formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	_, err = s.buffer.Write(rendered)
+	return err
+}
+
+//openLocked opens (or reopens, after rotation) the file for appending and
+//records its current size for MaxSizeMB rotation. Callers must hold mux,
+//except during construction where there is no concurrent access yet.
+func (s *fileSink) openLocked() error {
+	if s.fileName == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(s.fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	s.file = f
+	s.fileSize = info.Size()
+	return nil
+}
+
+//Flush writes any buffered entries to disk.
+func (s *fileSink) Flush() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.flushLocked()
+}
+
+func (s *fileSink) flushLocked() error {
+	if s.buffer.Len() == 0 {
+		return nil
+	}
+
+	if s.file == nil {
+		//No file to write to (e.g. FileConfig.FileName was empty): drop
+		//the buffered bytes instead of letting them accumulate forever.
+		s.buffer.Reset()
+		return nil
+	}
+
+	n, err := s.file.Write(s.buffer.Bytes())
+	s.fileSize += int64(n)
+	s.buffer.Reset()
+	if err != nil {
+		return err
+	}
+
+	if s.maxSizeMB > 0 && s.fileSize >= s.maxSizeMB*1024*1024 {
+		return s.rotateLocked()
+	}
+	return nil
+}
+
+//Rotate closes the current file, renames it with a timestamp suffix and
+//opens a fresh one in its place, pruning old backups past MaxBackups.
+func (s *fileSink) Rotate() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if err := s.flushLocked(); err != nil {
+		return err
+	}
+	return s.rotateLocked()
+}
+
+func (s *fileSink) rotateLocked() error {
+	if s.fileName == "" {
+		return nil
+	}
+
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+
+	backupName := fmt.Sprintf("%s.%s", s.fileName, time.Now().Format("20060102-150405"))
+	if err := os.Rename(s.fileName, backupName); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	s.pruneBackups()
+
+	return s.openLocked()
+}
+
+//pruneBackups removes the oldest rotated files beyond MaxBackups. A
+//MaxBackups of zero keeps every backup.
+func (s *fileSink) pruneBackups() {
+	if s.maxBackups <= 0 || s.fileName == "" {
+		return
+	}
+
+	matches, err := filepath.Glob(s.fileName + ".*")
+	if err != nil || len(matches) <= s.maxBackups {
+		return
+	}
+
+	sort.Strings(matches)
+	for _, stale := range matches[:len(matches)-s.maxBackups] {
+		os.Remove(stale)
+	}
+}
+
+func (s *fileSink) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+
+	if err := s.Flush(); err != nil {
+		return err
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,75 @@
+package snetlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSinkFlushWithNoFileResetsBuffer(t *testing.T) {
+	s := newFileSink(&FileConfig{})
+	defer s.Close()
+
+	if err := s.Write(Entry{Message: "hello"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if s.buffer.Len() == 0 {
+		t.Fatal("expected the write to land in the buffer")
+	}
+
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if s.buffer.Len() != 0 {
+		t.Fatalf("buffer.Len() = %d after Flush with no file, want 0", s.buffer.Len())
+	}
+}
+
+func TestFileSinkRotateStartsAFreshFileAndKeepsABackup(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "app.log")
+
+	s := newFileSink(&FileConfig{FileName: fileName, MaxBackups: 5})
+	defer s.Close()
+
+	if err := s.Write(Entry{Message: "before rotation"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := s.Rotate(); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+
+	if _, err := os.Stat(fileName); err != nil {
+		t.Fatalf("expected a fresh file at %q after rotation: %v", fileName, err)
+	}
+
+	matches, err := filepath.Glob(fileName + ".*")
+	if err != nil {
+		t.Fatalf("Glob failed: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected exactly one rotated backup, got %v", matches)
+	}
+}
+
+func TestFileSinkPruneBackupsCapsAtMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "app.log")
+
+	s := newFileSink(&FileConfig{FileName: fileName, MaxBackups: 2})
+	defer s.Close()
+
+	for i := 0; i < 4; i++ {
+		if err := s.Rotate(); err != nil {
+			t.Fatalf("Rotate %d failed: %v", i, err)
+		}
+	}
+
+	matches, err := filepath.Glob(fileName + ".*")
+	if err != nil {
+		t.Fatalf("Glob failed: %v", err)
+	}
+	if len(matches) > 2 {
+		t.Fatalf("expected at most 2 backups, got %d: %v", len(matches), matches)
+	}
+}
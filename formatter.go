@@ -0,0 +1,66 @@
+package snetlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+//Formatter renders an Entry into the bytes written to a sink (console,
+//file, ...). Sinks that take structured payloads directly (e.g.
+//Stackdriver) don't go through a Formatter.
+type Formatter interface {
+	Format(entry Entry) ([]byte, error)
+}
+
+//TextFormatter renders entries the way snetlog always has: a severity tag,
+//a timestamp and the message, followed by any fields as key=value pairs.
+type TextFormatter struct{}
+
+//Format ...
+func (f TextFormatter) Format(entry Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(formats[entry.Severity])
+	buf.WriteString(formataTimePadraoLog(entry.Time))
+	buf.WriteString(": ")
+	buf.WriteString(entry.Message)
+	for k, v := range entry.Fields {
+		fmt.Fprintf(&buf, " %s=%v", k, v)
+	}
+	if entry.Caller != nil {
+		fmt.Fprintf(&buf, " (%s:%d)", entry.Caller.File, entry.Caller.Line)
+	}
+	buf.WriteString("\n")
+	if entry.Stack != "" {
+		buf.WriteString(entry.Stack)
+	}
+	return buf.Bytes(), nil
+}
+
+//JSONFormatter renders each entry as a single JSON object per line,
+//combining the message and fields alongside the severity and timestamp.
+type JSONFormatter struct{}
+
+//Format ...
+func (f JSONFormatter) Format(entry Entry) ([]byte, error) {
+	payload := make(map[string]interface{}, len(entry.Fields)+3)
+	for k, v := range entry.Fields {
+		payload[k] = v
+	}
+	payload["severity"] = entry.Severity.String()
+	payload["time"] = entry.Time.Format(time.RFC3339Nano)
+	payload["message"] = entry.Message
+	if entry.Caller != nil {
+		payload["caller"] = fmt.Sprintf("%s:%d", entry.Caller.File, entry.Caller.Line)
+	}
+	if entry.Stack != "" {
+		payload["stack"] = entry.Stack
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
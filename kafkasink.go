@@ -0,0 +1,52 @@
+package snetlog
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/logging"
+	"github.com/segmentio/kafka-go"
+)
+
+//KafkaConfig configures a Kafka notification sink: entries are marshaled
+//to JSON and published to Topic.
+type KafkaConfig struct {
+	Brokers []string
+	Topic   string
+
+	//MinSeverity filters out entries below this severity before they
+	//reach Kafka. The zero value (logging.Default) accepts everything.
+	MinSeverity logging.Severity
+}
+
+type kafkaSink struct {
+	writer *kafka.Writer
+}
+
+func newKafkaSink(config *KafkaConfig) *kafkaSink {
+	return &kafkaSink{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(config.Brokers...),
+			Topic:    config.Topic,
+			Balancer: &kafka.LeastBytes{},
+		},
+	}
+}
+
+func (k *kafkaSink) Write(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return k.writer.WriteMessages(context.Background(), kafka.Message{Value: data})
+}
+
+func (k *kafkaSink) Flush() error { return nil }
+func (k *kafkaSink) Close() error { return k.writer.Close() }
+
+//NewLogKafka ships log entries to a Kafka topic. The returned Log has the
+//Kafka sink as its only sink; the Kafka sink type is unexported, so it
+//cannot be passed to New alongside other sinks from outside this package.
+func NewLogKafka(config *KafkaConfig) *Log {
+	return New(SinkConfig{Sink: newKafkaSink(config), MinSeverity: config.MinSeverity})
+}
@@ -0,0 +1,141 @@
+package snetlog
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+//logRegistry tracks every live *Log so a single process-wide signal
+//handler can act on all of them together, instead of each Log installing
+//its own competing handler.
+var logRegistry = struct {
+	mu   sync.Mutex
+	logs []*Log
+}{}
+
+func registerLog(l *Log) {
+	logRegistry.mu.Lock()
+	logRegistry.logs = append(logRegistry.logs, l)
+	logRegistry.mu.Unlock()
+
+	signalWatcherOnce.Do(startSignalWatcher)
+}
+
+func unregisterLog(l *Log) {
+	logRegistry.mu.Lock()
+	defer logRegistry.mu.Unlock()
+
+	for i, r := range logRegistry.logs {
+		if r == l {
+			logRegistry.logs = append(logRegistry.logs[:i], logRegistry.logs[i+1:]...)
+			break
+		}
+	}
+}
+
+func registeredLogs() []*Log {
+	logRegistry.mu.Lock()
+	defer logRegistry.mu.Unlock()
+
+	logs := make([]*Log, len(logRegistry.logs))
+	copy(logs, logRegistry.logs)
+	return logs
+}
+
+//signalWatcherOnce ensures only one goroutine ever watches
+//SIGHUP/SIGTERM/SIGINT, no matter how many Logs get constructed.
+var signalWatcherOnce sync.Once
+
+//startSignalWatcher installs the single process-wide handler: SIGHUP
+//rotates every registered Log; SIGTERM/SIGINT flushes and closes every
+//registered Log, then stops watching and re-raises the signal so the
+//process's own default disposition (or any other handler the embedding
+//application installed, e.g. for its own graceful shutdown) takes over
+//from there. It deliberately never calls os.Exit itself, so constructing
+//a Log doesn't hijack the application's shutdown sequence.
+func startSignalWatcher() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP, syscall.SIGTERM, syscall.SIGINT)
+
+	go func() {
+		for sig := range sigCh {
+			switch sig {
+			case syscall.SIGHUP:
+				for _, l := range registeredLogs() {
+					if err := l.Rotate(); err != nil {
+						log.Printf("snetlog: rotate on SIGHUP failed: %v", err)
+					}
+				}
+
+			case syscall.SIGTERM, syscall.SIGINT:
+				for _, l := range registeredLogs() {
+					l.Flush()
+					l.Close()
+				}
+
+				signal.Stop(sigCh)
+				if sysSig, ok := sig.(syscall.Signal); ok {
+					syscall.Kill(syscall.Getpid(), sysSig)
+				}
+				return
+			}
+		}
+	}()
+}
+
+//Stats reports how many entries have been enqueued, dropped and flushed
+//across all of this Log's sinks.
+func (l *Log) Stats() Stats {
+	var total Stats
+	for _, s := range l.sinks {
+		st := s.stats()
+		total.Enqueued += st.Enqueued
+		total.Dropped += st.Dropped
+		total.Flushed += st.Flushed
+	}
+	return total
+}
+
+//Flush flushes every configured sink (e.g. writing buffered file-sink
+//entries to disk). It is safe to call concurrently.
+func (l *Log) Flush() error {
+	var firstErr error
+	for _, s := range l.sinks {
+		if err := s.Flush(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+//Rotate rotates every sink that supports it (currently the file sink). It
+//can be triggered manually or via SIGHUP.
+func (l *Log) Rotate() error {
+	var firstErr error
+	for _, s := range l.sinks {
+		if err := s.Rotate(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+//Close removes this Log from the signal registry and closes every
+//configured sink, after draining anything still queued for them. It is
+//safe to call more than once.
+func (l *Log) Close() error {
+	l.closeOnce.Do(func() {
+		unregisterLog(l)
+	})
+
+	var firstErr error
+	for _, s := range l.sinks {
+		if err := s.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
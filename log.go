@@ -1,11 +1,7 @@
 package snetlog
 
 import (
-	"bytes"
 	"fmt"
-	"io/ioutil"
-	"log"
-	"os"
 	"sync"
 	"time"
 
@@ -28,6 +24,13 @@ type Logger interface {
 	Alertf(format string, args ...interface{})
 	Emergency(args ...interface{})
 	Emergencyf(format string, args ...interface{})
+
+	//WithField returns a child Logger that annotates every entry it logs
+	//with key=value, in addition to any fields already present.
+	WithField(key string, value interface{}) Logger
+	//WithFields returns a child Logger that annotates every entry it logs
+	//with fields, in addition to any fields already present.
+	WithFields(fields map[string]interface{}) Logger
 }
 
 //formats ...
@@ -41,19 +44,15 @@ var formats = map[logging.Severity]string{
 	logging.Emergency: "[EMERGENCY] ",
 }
 
-//Log ...
+//Log fans every entry out to a set of Sinks (console, file, Stackdriver,
+//or a notification backend such as Kafka/NATS/webhook). Construct one with
+//New, or with one of the NewLog* convenience wrappers.
 type Log struct {
-	enableConsole     bool
-	enableFile        bool
-	enableStackDriver bool
-
-	muxConsole sync.Mutex
-
-	muxFile    sync.Mutex
-	fileName   string
-	bufferFile *bytes.Buffer
+	sinks []*asyncSink
 
-	logStackdriver *logging.Logger
+	//closeOnce guards Close so it only unregisters this Log from the
+	//signal watcher (see lifecycle.go) once.
+	closeOnce sync.Once
 }
 
 //f retorna um string apresentando t no formato DD/MM/AAAA hh:mm:ss.milisegundo
@@ -63,160 +62,258 @@ func formataTimePadraoLog(t time.Time) string {
 	return momento
 }
 
-func flushLogFile(log *Log) {
-	ticker := time.NewTicker(1 * time.Second)
-	for {
-		select {
-		case <-ticker.C:
-			log.muxFile.Lock()
-			if log.bufferFile != nil {
-				if log.bufferFile.Len() > 0 && log.fileName != "" {
-					ioutil.WriteFile(log.fileName, log.bufferFile.Bytes(), os.ModeAppend)
-				}
-				log.bufferFile.Reset()
-				log.muxFile.Unlock()
-			}
-		}
+//stackdriverPayload builds the Stackdriver Entry.Payload for an entry: a
+//plain string when there are no fields, caller or stack (matching
+//historical behaviour), or a structured map when there are.
+func stackdriverPayload(entry Entry) interface{} {
+	if len(entry.Fields) == 0 && entry.Caller == nil && entry.Stack == "" {
+		return entry.Message
+	}
+
+	payload := make(map[string]interface{}, len(entry.Fields)+3)
+	for k, v := range entry.Fields {
+		payload[k] = v
+	}
+	payload["message"] = entry.Message
+	if entry.Caller != nil {
+		payload["caller"] = fmt.Sprintf("%s:%d", entry.Caller.File, entry.Caller.Line)
+	}
+	if entry.Stack != "" {
+		payload["stack"] = entry.Stack
+	}
+	return payload
+}
+
+//stackdriverLabels stringifies fields into the map[string]string that
+//logging.Entry.Labels requires.
+func stackdriverLabels(fields map[string]interface{}) map[string]string {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	labels := make(map[string]string, len(fields))
+	for k, v := range fields {
+		labels[k] = fmt.Sprint(v)
+	}
+	return labels
+}
+
+//New creates a Log that fans entries out to the given sinks, each gated
+//by its own minimum severity.
+func New(configs ...SinkConfig) *Log {
+	l := &Log{}
+	for _, c := range configs {
+		l.sinks = append(l.sinks, newAsyncSink(c.Sink, c.MinSeverity, c.Overflow))
 	}
+
+	registerLog(l)
+
+	return l
 }
 
-func (l *Log) output(severity logging.Severity, format string, args ...interface{}) {
-	if l.enableStackDriver && l.logStackdriver != nil {
-		if format != "" {
-			l.logStackdriver.Log(logging.Entry{Severity: severity,
-				Payload: fmt.Sprintf(format, args...)})
-		} else {
-			if l.logStackdriver != nil {
-				l.logStackdriver.Log(logging.Entry{Severity: severity, Payload: fmt.Sprint(args...)})
-			}
-		}
+//needsStack reports whether severity warrants an automatic stack dump:
+//Erro, Alert and Emergency always do, since those are the levels worth
+//waking someone up for.
+func needsStack(severity logging.Severity) bool {
+	return severity == logging.Error || severity == logging.Alert || severity == logging.Emergency
+}
+
+//output renders and dispatches a log entry. depth is the number of extra
+//stack frames to skip beyond the logging method's own frame when
+//resolving the caller; it's always 0 except from a *Depth method, where
+//it's the caller-supplied value for wrapper libraries reporting their own
+//caller's location.
+func (l *Log) output(severity logging.Severity, fields map[string]interface{}, depth int, format string, args ...interface{}) {
+	var message string
+	if format == "" {
+		message = fmt.Sprint(args...)
+	} else {
+		message = fmt.Sprintf(format, args...)
 	}
 
-	if l.enableConsole {
-		l.muxConsole.Lock()
-		log.Printf(formats[severity])
-		fmt.Printf(formataTimePadraoLog(time.Now()) + ": ")
-		if format == "" {
-			fmt.Printf(fmt.Sprint(args...))
-		} else {
-			fmt.Printf(format, args...)
-		}
-		fmt.Printf("\n")
-		l.muxConsole.Unlock()
+	caller := resolveCaller(callerBaseSkip + depth)
+
+	entry := Entry{Severity: severity, Time: time.Now(), Message: message, Fields: fields, Caller: caller}
+	if needsStack(severity) || shouldBacktrace(caller) {
+		//runtime.Callers counts its own frame where runtime.Caller does
+		//not, so the stack skips one frame more than the caller lookup.
+		entry.Stack = captureStack(callerBaseSkip + 1 + depth)
 	}
 
-	if l.enableFile && l.fileName != "" && l.bufferFile != nil {
-		l.muxFile.Lock()
-		l.bufferFile.WriteString(formats[severity])
-		l.bufferFile.WriteString(formataTimePadraoLog(time.Now()) + ": ")
-		if format == "" {
-			l.bufferFile.WriteString(fmt.Sprint(args...))
-		} else {
-			l.bufferFile.WriteString(fmt.Sprintf(format, args...))
-		}
-		l.bufferFile.WriteString("\n")
-		l.muxFile.Unlock()
+	for _, s := range l.sinks {
+		s.write(entry)
 	}
 }
 
 //Trace ...
 func (l *Log) Trace(args ...interface{}) {
-	l.output(logging.Debug, "", args...)
+	l.output(logging.Debug, nil, 0, "", args...)
 }
 
 //Tracef ...
 func (l *Log) Tracef(format string, args ...interface{}) {
-	l.output(logging.Debug, format, args...)
+	l.output(logging.Debug, nil, 0, format, args...)
+}
+
+//TraceDepth logs at Trace severity, attributing the entry to the caller
+//depth frames above this call instead of to this call itself. It's meant
+//for wrapper libraries that want Trace to report their own caller.
+func (l *Log) TraceDepth(depth int, args ...interface{}) {
+	l.output(logging.Debug, nil, depth, "", args...)
 }
 
 //Info ...
 func (l *Log) Info(args ...interface{}) {
-	l.output(logging.Info, "", args...)
+	l.output(logging.Info, nil, 0, "", args...)
 }
 
 //Infof ...
 func (l *Log) Infof(format string, args ...interface{}) {
-	l.output(logging.Debug, format, args...)
+	l.output(logging.Info, nil, 0, format, args...)
+}
+
+//InfoDepth logs at Info severity, attributing the entry to the caller
+//depth frames above this call instead of to this call itself.
+func (l *Log) InfoDepth(depth int, args ...interface{}) {
+	l.output(logging.Info, nil, depth, "", args...)
 }
 
 //Notice ...
 func (l *Log) Notice(args ...interface{}) {
-	l.output(logging.Notice, "", args...)
+	l.output(logging.Notice, nil, 0, "", args...)
 }
 
 //Noticef ...
 func (l *Log) Noticef(format string, args ...interface{}) {
-	l.output(logging.Notice, format, args...)
+	l.output(logging.Notice, nil, 0, format, args...)
+}
+
+//NoticeDepth logs at Notice severity, attributing the entry to the caller
+//depth frames above this call instead of to this call itself.
+func (l *Log) NoticeDepth(depth int, args ...interface{}) {
+	l.output(logging.Notice, nil, depth, "", args...)
 }
 
 //Erro ...
 func (l *Log) Erro(args ...interface{}) {
-	l.output(logging.Error, "", args...)
+	l.output(logging.Error, nil, 0, "", args...)
 }
 
 //Errof ...
 func (l *Log) Errof(format string, args ...interface{}) {
-	l.output(logging.Error, format, args...)
+	l.output(logging.Error, nil, 0, format, args...)
+}
+
+//ErroDepth logs at Error severity, attributing the entry (and its
+//automatic stack dump) to the caller depth frames above this call instead
+//of to this call itself. It's meant for wrapper libraries that want Erro
+//to report the real call site rather than the wrapper's own location.
+func (l *Log) ErroDepth(depth int, args ...interface{}) {
+	l.output(logging.Error, nil, depth, "", args...)
 }
 
 //Warn ...
 func (l *Log) Warn(args ...interface{}) {
-	l.output(logging.Warning, "", args...)
+	l.output(logging.Warning, nil, 0, "", args...)
 }
 
 //Warnf ...
 func (l *Log) Warnf(format string, args ...interface{}) {
-	l.output(logging.Warning, format, args...)
+	l.output(logging.Warning, nil, 0, format, args...)
+}
+
+//WarnDepth logs at Warning severity, attributing the entry to the caller
+//depth frames above this call instead of to this call itself.
+func (l *Log) WarnDepth(depth int, args ...interface{}) {
+	l.output(logging.Warning, nil, depth, "", args...)
 }
 
 //Alert ...
 func (l *Log) Alert(args ...interface{}) {
-	l.output(logging.Alert, "", args...)
+	l.output(logging.Alert, nil, 0, "", args...)
 }
 
 //Alertf ...
 func (l *Log) Alertf(format string, args ...interface{}) {
-	l.output(logging.Alert, format, args...)
+	l.output(logging.Alert, nil, 0, format, args...)
+}
+
+//AlertDepth logs at Alert severity, attributing the entry (and its
+//automatic stack dump) to the caller depth frames above this call instead
+//of to this call itself.
+func (l *Log) AlertDepth(depth int, args ...interface{}) {
+	l.output(logging.Alert, nil, depth, "", args...)
 }
 
 //Emergency ...
 func (l *Log) Emergency(args ...interface{}) {
-	l.output(logging.Emergency, "", args...)
+	l.output(logging.Emergency, nil, 0, "", args...)
 }
 
 //Emergencyf ...
 func (l *Log) Emergencyf(format string, args ...interface{}) {
-	l.output(logging.Emergency, format, args...)
+	l.output(logging.Emergency, nil, 0, format, args...)
+}
+
+//EmergencyDepth logs at Emergency severity, attributing the entry (and
+//its automatic stack dump) to the caller depth frames above this call
+//instead of to this call itself.
+func (l *Log) EmergencyDepth(depth int, args ...interface{}) {
+	l.output(logging.Emergency, nil, depth, "", args...)
 }
 
 //FileConfig ...
 type FileConfig struct {
-	FileName string
+	FileName  string
+	Formatter Formatter
+
+	//MinSeverity filters out entries below this severity before they
+	//reach the file. The zero value (logging.Default) accepts everything.
+	MinSeverity logging.Severity
+
+	//MaxSizeMB rotates the log file once it reaches this size, in
+	//megabytes. Zero disables size-based rotation.
+	MaxSizeMB int64
+	//MaxBackups caps how many rotated files are kept alongside the
+	//active log file; the oldest are removed first. Zero keeps all of
+	//them.
+	MaxBackups int
 }
 
 //NewLogFile ...
 func NewLogFile(config *FileConfig) *Log {
-	log := &Log{
-		enableFile: true,
-		fileName:   config.FileName,
-		bufferFile: bytes.NewBuffer(make([]byte, 0, 3072)),
-	}
-
-	go flushLogFile(log)
-
-	return log
+	return New(SinkConfig{Sink: newFileSink(config), MinSeverity: config.MinSeverity})
 }
 
 //ConsoleConfig ...
 type ConsoleConfig struct {
-	FileName string
+	FileName  string
+	Formatter Formatter
+
+	//MinSeverity filters out entries below this severity before they
+	//reach the console. The zero value (logging.Default) accepts
+	//everything.
+	MinSeverity logging.Severity
 }
 
 //NewLogConsole ...
 func NewLogConsole(config *ConsoleConfig) *Log {
-	log := &Log{
-		enableConsole: true,
-	}
+	sink := &consoleSink{formatter: config.Formatter}
+	return New(SinkConfig{Sink: sink, MinSeverity: config.MinSeverity})
+}
+
+//StackdriverConfig ...
+type StackdriverConfig struct {
+	Logger *logging.Logger
+
+	//MinSeverity filters out entries below this severity before they
+	//reach Stackdriver. The zero value (logging.Default) accepts
+	//everything.
+	MinSeverity logging.Severity
+}
 
-	return log
+//NewLogStackdriver ...
+func NewLogStackdriver(config *StackdriverConfig) *Log {
+	sink := &stackdriverSink{logger: config.Logger}
+	return New(SinkConfig{Sink: sink, MinSeverity: config.MinSeverity})
 }
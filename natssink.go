@@ -0,0 +1,58 @@
+package snetlog
+
+import (
+	"encoding/json"
+
+	"cloud.google.com/go/logging"
+	"github.com/nats-io/nats.go"
+)
+
+//NATSConfig configures a NATS notification sink: entries are marshaled to
+//JSON and published on Subject.
+type NATSConfig struct {
+	URL     string
+	Subject string
+
+	//MinSeverity filters out entries below this severity before they
+	//reach NATS. The zero value (logging.Default) accepts everything.
+	MinSeverity logging.Severity
+}
+
+type natsSink struct {
+	conn    *nats.Conn
+	subject string
+}
+
+func newNATSSink(config *NATSConfig) (*natsSink, error) {
+	conn, err := nats.Connect(config.URL)
+	if err != nil {
+		return nil, err
+	}
+	return &natsSink{conn: conn, subject: config.Subject}, nil
+}
+
+func (n *natsSink) Write(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return n.conn.Publish(n.subject, data)
+}
+
+func (n *natsSink) Flush() error { return n.conn.Flush() }
+
+func (n *natsSink) Close() error {
+	n.conn.Close()
+	return nil
+}
+
+//NewLogNATS ships log entries to a NATS subject. The returned Log has the
+//NATS sink as its only sink; the NATS sink type is unexported, so it
+//cannot be passed to New alongside other sinks from outside this package.
+func NewLogNATS(config *NATSConfig) (*Log, error) {
+	sink, err := newNATSSink(config)
+	if err != nil {
+		return nil, err
+	}
+	return New(SinkConfig{Sink: sink, MinSeverity: config.MinSeverity}), nil
+}
@@ -0,0 +1,241 @@
+package snetlog
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"cloud.google.com/go/logging"
+)
+
+//Sink is a logging backend: something that can accept Entries, flush any
+//internal buffering, and shut down cleanly. A Log fans every entry out to
+//its configured Sinks.
+type Sink interface {
+	Write(entry Entry) error
+	Flush() error
+	Close() error
+}
+
+//Rotator is implemented by sinks that support log rotation (currently
+//just the file sink). Log.Rotate calls it on any sink that implements it.
+type Rotator interface {
+	Rotate() error
+}
+
+//OverflowPolicy controls what happens when a sink's bounded queue is full.
+type OverflowPolicy int
+
+const (
+	//DropNewest discards the entry that doesn't fit, leaving the queue
+	//as-is. This is the default.
+	DropNewest OverflowPolicy = iota
+	//DropOldest discards the queue's oldest unwritten entry to make room
+	//for the new one.
+	DropOldest
+	//BlockOnFull makes the caller wait until the sink's goroutine has
+	//drained enough of the queue to accept the entry.
+	BlockOnFull
+)
+
+//Stats reports how a Log's sinks have handled entries handed to them.
+//Enqueued and Dropped are counted when output() hands an entry to a
+//sink's queue; Flushed is counted once the sink's goroutine has actually
+//written it out.
+type Stats struct {
+	Enqueued int64
+	Dropped  int64
+	Flushed  int64
+}
+
+//SinkConfig pairs a Sink with the minimum severity it should receive and
+//how it behaves when its queue backs up.
+type SinkConfig struct {
+	Sink        Sink
+	MinSeverity logging.Severity
+	Overflow    OverflowPolicy
+}
+
+//sinkQueueSize bounds the per-sink channel used by asyncSink so a slow
+//backend (a stuck webhook, a full Kafka buffer) queues up rather than
+//blocking the caller's goroutine, unless it's configured with
+//BlockOnFull.
+const sinkQueueSize = 256
+
+//asyncSink runs a Sink's writes on a dedicated goroutine fed by a bounded
+//channel, isolating slow backends from callers and from each other.
+type asyncSink struct {
+	sink     Sink
+	minLevel logging.Severity
+	policy   OverflowPolicy
+	entries  chan Entry
+	done     chan struct{}
+
+	//closeMu guards closed: Close takes the write lock so it can only
+	//close entries once every in-flight write (each holding the read
+	//lock) has finished sending, which is what keeps write from ever
+	//sending on a closed channel.
+	closeMu sync.RWMutex
+	closed  bool
+
+	enqueued int64
+	dropped  int64
+	flushed  int64
+}
+
+func newAsyncSink(sink Sink, minLevel logging.Severity, policy OverflowPolicy) *asyncSink {
+	a := &asyncSink{
+		sink:     sink,
+		minLevel: minLevel,
+		policy:   policy,
+		entries:  make(chan Entry, sinkQueueSize),
+		done:     make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+func (a *asyncSink) run() {
+	defer close(a.done)
+	for entry := range a.entries {
+		if err := a.sink.Write(entry); err != nil {
+			log.Printf("snetlog: sink write failed: %v", err)
+			continue
+		}
+		atomic.AddInt64(&a.flushed, 1)
+	}
+}
+
+//write hands entry to the sink's goroutine, applying the configured
+//OverflowPolicy when the queue is full. It's a no-op once Close has
+//started, so it never sends on the channel Close is about to close.
+func (a *asyncSink) write(entry Entry) {
+	if entry.Severity < a.minLevel {
+		return
+	}
+
+	a.closeMu.RLock()
+	defer a.closeMu.RUnlock()
+	if a.closed {
+		return
+	}
+
+	switch a.policy {
+	case BlockOnFull:
+		a.entries <- entry
+		atomic.AddInt64(&a.enqueued, 1)
+
+	case DropOldest:
+		for {
+			select {
+			case a.entries <- entry:
+				atomic.AddInt64(&a.enqueued, 1)
+				return
+			default:
+				select {
+				case <-a.entries:
+					atomic.AddInt64(&a.dropped, 1)
+				default:
+				}
+			}
+		}
+
+	default: // DropNewest
+		select {
+		case a.entries <- entry:
+			atomic.AddInt64(&a.enqueued, 1)
+		default:
+			atomic.AddInt64(&a.dropped, 1)
+		}
+	}
+}
+
+func (a *asyncSink) stats() Stats {
+	return Stats{
+		Enqueued: atomic.LoadInt64(&a.enqueued),
+		Dropped:  atomic.LoadInt64(&a.dropped),
+		Flushed:  atomic.LoadInt64(&a.flushed),
+	}
+}
+
+func (a *asyncSink) Flush() error {
+	return a.sink.Flush()
+}
+
+func (a *asyncSink) Rotate() error {
+	if r, ok := a.sink.(Rotator); ok {
+		return r.Rotate()
+	}
+	return nil
+}
+
+func (a *asyncSink) Close() error {
+	a.closeMu.Lock()
+	alreadyClosed := a.closed
+	a.closed = true
+	a.closeMu.Unlock()
+
+	if alreadyClosed {
+		return nil
+	}
+
+	close(a.entries)
+	<-a.done
+	return a.sink.Close()
+}
+
+//consoleSink writes entries to stdout, serialized with a Formatter
+//(TextFormatter by default).
+type consoleSink struct {
+	mux       sync.Mutex
+	formatter Formatter
+}
+
+func (c *consoleSink) Write(entry Entry) error {
+	formatter := c.formatter
+	if formatter == nil {
+		formatter = TextFormatter{}
+	}
+
+	rendered, err := formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	_, err = fmt.Print(string(rendered))
+	return err
+}
+
+func (c *consoleSink) Flush() error { return nil }
+func (c *consoleSink) Close() error { return nil }
+
+//stackdriverSink forwards entries to a Stackdriver *logging.Logger,
+//passing fields through as both the structured Payload and as Labels.
+type stackdriverSink struct {
+	logger *logging.Logger
+}
+
+func (s *stackdriverSink) Write(entry Entry) error {
+	if s.logger == nil {
+		return nil
+	}
+
+	s.logger.Log(logging.Entry{
+		Severity: entry.Severity,
+		Payload:  stackdriverPayload(entry),
+		Labels:   stackdriverLabels(entry.Fields),
+	})
+	return nil
+}
+
+func (s *stackdriverSink) Flush() error {
+	if s.logger == nil {
+		return nil
+	}
+	return s.logger.Flush()
+}
+
+func (s *stackdriverSink) Close() error { return nil }
@@ -0,0 +1,117 @@
+package snetlog
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging"
+)
+
+//blockingSink is a Sink whose Write blocks until released, so tests can
+//force a sink's goroutine to stall and back its queue up.
+type blockingSink struct {
+	release chan struct{}
+	written int64
+}
+
+func (b *blockingSink) Write(entry Entry) error {
+	<-b.release
+	atomic.AddInt64(&b.written, 1)
+	return nil
+}
+
+func (b *blockingSink) Flush() error { return nil }
+func (b *blockingSink) Close() error { return nil }
+
+func TestAsyncSinkDropNewestDiscardsWhenFull(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{})}
+	a := newAsyncSink(sink, logging.Default, DropNewest)
+	defer func() {
+		close(sink.release)
+		a.Close()
+	}()
+
+	for i := 0; i < sinkQueueSize+5; i++ {
+		a.write(Entry{Severity: logging.Info})
+	}
+
+	st := a.stats()
+	if st.Dropped == 0 {
+		t.Fatalf("expected some entries to be dropped, got stats %+v", st)
+	}
+	if st.Enqueued+st.Dropped != sinkQueueSize+5 {
+		t.Fatalf("enqueued+dropped = %d, want %d", st.Enqueued+st.Dropped, sinkQueueSize+5)
+	}
+}
+
+func TestAsyncSinkDropOldestMakesRoom(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{})}
+	a := newAsyncSink(sink, logging.Default, DropOldest)
+	defer func() {
+		close(sink.release)
+		a.Close()
+	}()
+
+	for i := 0; i < sinkQueueSize+5; i++ {
+		a.write(Entry{Severity: logging.Info})
+	}
+
+	st := a.stats()
+	if st.Dropped == 0 {
+		t.Fatalf("expected DropOldest to discard entries to make room, got stats %+v", st)
+	}
+	if len(a.entries) != sinkQueueSize {
+		t.Fatalf("queue length = %d, want it full at %d", len(a.entries), sinkQueueSize)
+	}
+}
+
+func TestAsyncSinkBlockOnFullWaitsForRoom(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{})}
+	a := newAsyncSink(sink, logging.Default, BlockOnFull)
+	defer a.Close()
+
+	for i := 0; i < sinkQueueSize; i++ {
+		a.write(Entry{Severity: logging.Info})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		a.write(Entry{Severity: logging.Info})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("write returned before the queue had room")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(sink.release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("write never unblocked once the sink drained")
+	}
+}
+
+func TestAsyncSinkCloseDuringConcurrentWriteDoesNotPanic(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{})}
+	close(sink.release)
+	a := newAsyncSink(sink, logging.Default, DropNewest)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.write(Entry{Severity: logging.Info})
+		}()
+	}
+
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,230 @@
+package snetlog
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"cloud.google.com/go/logging"
+)
+
+var globalVerbosity int32
+
+var vmodule = struct {
+	mu    sync.RWMutex
+	rules []vmoduleRule
+}{}
+
+type vmoduleRule struct {
+	pattern string
+	level   int32
+}
+
+//vmoduleCache memoizes the effective verbosity for a call site (keyed by
+//its runtime.Caller PC) so repeated V() calls from the same line don't pay
+//for a glob match on every invocation.
+var vmoduleCache sync.Map
+
+//resetVmoduleCache clears every cached entry in place. It must not
+//reassign vmoduleCache itself (e.g. `vmoduleCache = sync.Map{}`): that
+//races with the Load/Store calls verbosityForCaller makes concurrently
+//from V().
+func resetVmoduleCache() {
+	vmoduleCache.Range(func(key, _ interface{}) bool {
+		vmoduleCache.Delete(key)
+		return true
+	})
+}
+
+//SetVerbosity sets the global verbosity threshold used by V() for callers
+//that don't match a SetVModule pattern.
+func SetVerbosity(level int32) {
+	atomic.StoreInt32(&globalVerbosity, level)
+	resetVmoduleCache()
+}
+
+//SetVModule configures per-file verbosity overrides, glog-style:
+//"file1=2,pkg/*=3". Patterns are matched against the caller's file path
+//minus its ".go" suffix. A pattern with no "/" is matched against the
+//caller's base file name; a pattern with N path segments (e.g. "pkg/*") is
+//matched against the caller's last N path segments, so it matches regardless of
+//where the package lives on disk. The first matching rule wins.
+func SetVModule(spec string) error {
+	var rules []vmoduleRule
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("snetlog: invalid vmodule entry %q", part)
+		}
+
+		level, err := strconv.ParseInt(strings.TrimSpace(kv[1]), 10, 32)
+		if err != nil {
+			return fmt.Errorf("snetlog: invalid vmodule level in %q: %v", part, err)
+		}
+
+		rules = append(rules, vmoduleRule{pattern: strings.TrimSpace(kv[0]), level: int32(level)})
+	}
+
+	vmodule.mu.Lock()
+	vmodule.rules = rules
+	vmodule.mu.Unlock()
+	resetVmoduleCache()
+	return nil
+}
+
+func verbosityForCaller(pc uintptr, file string) int32 {
+	if cached, ok := vmoduleCache.Load(pc); ok {
+		return cached.(int32)
+	}
+
+	level := atomic.LoadInt32(&globalVerbosity)
+
+	vmodule.mu.RLock()
+	rules := vmodule.rules
+	vmodule.mu.RUnlock()
+
+	fileSegs := strings.Split(strings.TrimSuffix(filepath.ToSlash(file), ".go"), "/")
+	for _, rule := range rules {
+		patternSegs := strings.Split(rule.pattern, "/")
+		if len(patternSegs) > len(fileSegs) {
+			continue
+		}
+
+		suffix := strings.Join(fileSegs[len(fileSegs)-len(patternSegs):], "/")
+		if matched, _ := filepath.Match(rule.pattern, suffix); matched {
+			level = rule.level
+			break
+		}
+	}
+
+	vmoduleCache.Store(pc, level)
+	return level
+}
+
+//Verbose is returned by Log.V and gates logging calls on the verbosity
+//configured for the caller via SetVerbosity/SetVModule, the way glog's
+//V(level).Info(...) does. Calls made through a disabled Verbose are
+//no-ops: the format string and args are never touched.
+type Verbose struct {
+	log     *Log
+	enabled bool
+}
+
+//V reports whether logging at level is enabled for the caller. The
+//verbosity lookup for a given call site is cached by program counter, so
+//once verbosity has been resolved, leaving l.V(2).Tracef(...) calls in a
+//hot path costs a runtime.Caller call plus a single map lookup.
+func (l *Log) V(level int32) Verbose {
+	pc, file, _, ok := runtime.Caller(1)
+	if !ok {
+		return Verbose{log: l, enabled: level <= atomic.LoadInt32(&globalVerbosity)}
+	}
+	return Verbose{log: l, enabled: level <= verbosityForCaller(pc, file)}
+}
+
+//Trace ...
+func (v Verbose) Trace(args ...interface{}) {
+	if v.enabled {
+		v.log.output(logging.Debug, nil, 0, "", args...)
+	}
+}
+
+//Tracef ...
+func (v Verbose) Tracef(format string, args ...interface{}) {
+	if v.enabled {
+		v.log.output(logging.Debug, nil, 0, format, args...)
+	}
+}
+
+//Info ...
+func (v Verbose) Info(args ...interface{}) {
+	if v.enabled {
+		v.log.output(logging.Info, nil, 0, "", args...)
+	}
+}
+
+//Infof ...
+func (v Verbose) Infof(format string, args ...interface{}) {
+	if v.enabled {
+		v.log.output(logging.Info, nil, 0, format, args...)
+	}
+}
+
+//Notice ...
+func (v Verbose) Notice(args ...interface{}) {
+	if v.enabled {
+		v.log.output(logging.Notice, nil, 0, "", args...)
+	}
+}
+
+//Noticef ...
+func (v Verbose) Noticef(format string, args ...interface{}) {
+	if v.enabled {
+		v.log.output(logging.Notice, nil, 0, format, args...)
+	}
+}
+
+//Warn ...
+func (v Verbose) Warn(args ...interface{}) {
+	if v.enabled {
+		v.log.output(logging.Warning, nil, 0, "", args...)
+	}
+}
+
+//Warnf ...
+func (v Verbose) Warnf(format string, args ...interface{}) {
+	if v.enabled {
+		v.log.output(logging.Warning, nil, 0, format, args...)
+	}
+}
+
+//Erro ...
+func (v Verbose) Erro(args ...interface{}) {
+	if v.enabled {
+		v.log.output(logging.Error, nil, 0, "", args...)
+	}
+}
+
+//Errof ...
+func (v Verbose) Errof(format string, args ...interface{}) {
+	if v.enabled {
+		v.log.output(logging.Error, nil, 0, format, args...)
+	}
+}
+
+//Alert ...
+func (v Verbose) Alert(args ...interface{}) {
+	if v.enabled {
+		v.log.output(logging.Alert, nil, 0, "", args...)
+	}
+}
+
+//Alertf ...
+func (v Verbose) Alertf(format string, args ...interface{}) {
+	if v.enabled {
+		v.log.output(logging.Alert, nil, 0, format, args...)
+	}
+}
+
+//Emergency ...
+func (v Verbose) Emergency(args ...interface{}) {
+	if v.enabled {
+		v.log.output(logging.Emergency, nil, 0, "", args...)
+	}
+}
+
+//Emergencyf ...
+func (v Verbose) Emergencyf(format string, args ...interface{}) {
+	if v.enabled {
+		v.log.output(logging.Emergency, nil, 0, format, args...)
+	}
+}